@@ -0,0 +1,688 @@
+// Hand-written wire types and gRPC stubs for pkg/logproto/querier.proto.
+//
+// This is not protoc-gen-gogo output: it doesn't register a file descriptor
+// and is missing the XXX_ bookkeeping fields and GetXxx() accessors a real
+// `make protos` run would produce. It exists so QuerierService has working
+// Marshal/Unmarshal/ServiceDesc implementations in a tree where protoc isn't
+// available to generate them. Replace it with real generated output (and
+// delete this file) the next time `make protos` runs against querier.proto.
+
+package logproto
+
+import (
+	context "context"
+	fmt "fmt"
+	io "io"
+	math "math"
+	time "time"
+
+	proto "github.com/gogo/protobuf/proto"
+	types "github.com/gogo/protobuf/types"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type QueryRequest struct {
+	Query     string    `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Ts        time.Time `protobuf:"bytes,2,opt,name=ts,proto3,stdtime" json:"ts"`
+	Direction Direction `protobuf:"varint,3,opt,name=direction,proto3,enum=logproto.Direction" json:"direction,omitempty"`
+	Limit     uint32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *QueryRequest) Reset()         { *m = QueryRequest{} }
+func (m *QueryRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryRequest) ProtoMessage()    {}
+
+type QueryRangeRequest struct {
+	Query     string    `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Start     time.Time `protobuf:"bytes,2,opt,name=start,proto3,stdtime" json:"start"`
+	End       time.Time `protobuf:"bytes,3,opt,name=end,proto3,stdtime" json:"end"`
+	StepMs    int64     `protobuf:"varint,4,opt,name=step_ms,json=stepMs,proto3" json:"step_ms,omitempty"`
+	Direction Direction `protobuf:"varint,5,opt,name=direction,proto3,enum=logproto.Direction" json:"direction,omitempty"`
+	Limit     uint32    `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *QueryRangeRequest) Reset()         { *m = QueryRangeRequest{} }
+func (m *QueryRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryRangeRequest) ProtoMessage()    {}
+
+// QueryResponse carries the encoding/json encoding of a promql.Value and its
+// type, so this proto doesn't need its own copy of the PromQL result model.
+// It is not the response body the HTTP handlers return.
+type QueryResponse struct {
+	ResultType string `protobuf:"bytes,1,opt,name=result_type,json=resultType,proto3" json:"result_type,omitempty"`
+	Result     []byte `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (m *QueryResponse) Reset()         { *m = QueryResponse{} }
+func (m *QueryResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryResponse) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*QueryRequest)(nil), "logproto.QueryRequest")
+	proto.RegisterType((*QueryRangeRequest)(nil), "logproto.QueryRangeRequest")
+	proto.RegisterType((*QueryResponse)(nil), "logproto.QueryResponse")
+}
+
+func (m *QueryRequest) Marshal() ([]byte, error) {
+	size := m.Size()
+	data := make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(data[:size])
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *QueryRequest) MarshalToSizedBuffer(data []byte) (int, error) {
+	i := len(data)
+	i -= sovQuerier(uint64(m.Limit))
+	i = encodeVarintQuerier(data, i, uint64(m.Limit))
+	i--
+	data[i] = 0x20
+	i -= sovQuerier(uint64(m.Direction))
+	i = encodeVarintQuerier(data, i, uint64(m.Direction))
+	i--
+	data[i] = 0x18
+	ts, err := types.TimestampProto(m.Ts)
+	if err != nil {
+		return 0, err
+	}
+	tsBytes, err := proto.Marshal(ts)
+	if err != nil {
+		return 0, err
+	}
+	i -= len(tsBytes)
+	copy(data[i:], tsBytes)
+	i = encodeVarintQuerier(data, i, uint64(len(tsBytes)))
+	i--
+	data[i] = 0x12
+	i -= len(m.Query)
+	copy(data[i:], m.Query)
+	i = encodeVarintQuerier(data, i, uint64(len(m.Query)))
+	i--
+	data[i] = 0xa
+	return len(data) - i, nil
+}
+
+func (m *QueryRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	l := len(m.Query)
+	n += 1 + l + sovQuerier(uint64(l))
+	ts, _ := types.TimestampProto(m.Ts)
+	tl := proto.Size(ts)
+	n += 1 + tl + sovQuerier(uint64(tl))
+	n += 1 + sovQuerier(uint64(m.Direction))
+	n += 1 + sovQuerier(uint64(m.Limit))
+	return n
+}
+
+func (m *QueryRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		fieldNum, wireType, n, err := readQuerierTag(data, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = n
+		switch fieldNum {
+		case 1:
+			s, n, err := readQuerierString(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Query = s
+			iNdEx = n
+		case 2:
+			b, n, err := readQuerierBytes(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			ts := &types.Timestamp{}
+			if err := proto.Unmarshal(b, ts); err != nil {
+				return err
+			}
+			t, err := types.TimestampFromProto(ts)
+			if err != nil {
+				return err
+			}
+			m.Ts = t
+			iNdEx = n
+		case 3:
+			v, n, err := readQuerierVarint(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Direction = Direction(v)
+			iNdEx = n
+		case 4:
+			v, n, err := readQuerierVarint(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Limit = uint32(v)
+			iNdEx = n
+		default:
+			n, err := skipQuerier(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			iNdEx = n
+		}
+	}
+	return nil
+}
+
+func (m *QueryRangeRequest) Marshal() ([]byte, error) {
+	size := m.Size()
+	data := make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(data[:size])
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *QueryRangeRequest) MarshalToSizedBuffer(data []byte) (int, error) {
+	i := len(data)
+	i -= sovQuerier(uint64(m.Limit))
+	i = encodeVarintQuerier(data, i, uint64(m.Limit))
+	i--
+	data[i] = 0x30
+	i -= sovQuerier(uint64(m.Direction))
+	i = encodeVarintQuerier(data, i, uint64(m.Direction))
+	i--
+	data[i] = 0x28
+	i -= sovQuerier(uint64(m.StepMs))
+	i = encodeVarintQuerier(data, i, uint64(m.StepMs))
+	i--
+	data[i] = 0x20
+
+	end, err := types.TimestampProto(m.End)
+	if err != nil {
+		return 0, err
+	}
+	endBytes, err := proto.Marshal(end)
+	if err != nil {
+		return 0, err
+	}
+	i -= len(endBytes)
+	copy(data[i:], endBytes)
+	i = encodeVarintQuerier(data, i, uint64(len(endBytes)))
+	i--
+	data[i] = 0x1a
+
+	start, err := types.TimestampProto(m.Start)
+	if err != nil {
+		return 0, err
+	}
+	startBytes, err := proto.Marshal(start)
+	if err != nil {
+		return 0, err
+	}
+	i -= len(startBytes)
+	copy(data[i:], startBytes)
+	i = encodeVarintQuerier(data, i, uint64(len(startBytes)))
+	i--
+	data[i] = 0x12
+
+	i -= len(m.Query)
+	copy(data[i:], m.Query)
+	i = encodeVarintQuerier(data, i, uint64(len(m.Query)))
+	i--
+	data[i] = 0xa
+	return len(data) - i, nil
+}
+
+func (m *QueryRangeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	l := len(m.Query)
+	n += 1 + l + sovQuerier(uint64(l))
+
+	start, _ := types.TimestampProto(m.Start)
+	sl := proto.Size(start)
+	n += 1 + sl + sovQuerier(uint64(sl))
+
+	end, _ := types.TimestampProto(m.End)
+	el := proto.Size(end)
+	n += 1 + el + sovQuerier(uint64(el))
+
+	n += 1 + sovQuerier(uint64(m.StepMs))
+	n += 1 + sovQuerier(uint64(m.Direction))
+	n += 1 + sovQuerier(uint64(m.Limit))
+	return n
+}
+
+func (m *QueryRangeRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		fieldNum, wireType, n, err := readQuerierTag(data, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = n
+		switch fieldNum {
+		case 1:
+			s, n, err := readQuerierString(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Query = s
+			iNdEx = n
+		case 2:
+			b, n, err := readQuerierBytes(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			ts := &types.Timestamp{}
+			if err := proto.Unmarshal(b, ts); err != nil {
+				return err
+			}
+			t, err := types.TimestampFromProto(ts)
+			if err != nil {
+				return err
+			}
+			m.Start = t
+			iNdEx = n
+		case 3:
+			b, n, err := readQuerierBytes(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			ts := &types.Timestamp{}
+			if err := proto.Unmarshal(b, ts); err != nil {
+				return err
+			}
+			t, err := types.TimestampFromProto(ts)
+			if err != nil {
+				return err
+			}
+			m.End = t
+			iNdEx = n
+		case 4:
+			v, n, err := readQuerierVarint(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.StepMs = int64(v)
+			iNdEx = n
+		case 5:
+			v, n, err := readQuerierVarint(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Direction = Direction(v)
+			iNdEx = n
+		case 6:
+			v, n, err := readQuerierVarint(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Limit = uint32(v)
+			iNdEx = n
+		default:
+			n, err := skipQuerier(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			iNdEx = n
+		}
+	}
+	return nil
+}
+
+func (m *QueryResponse) Marshal() ([]byte, error) {
+	size := m.Size()
+	data := make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(data[:size])
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *QueryResponse) MarshalToSizedBuffer(data []byte) (int, error) {
+	i := len(data)
+	i -= len(m.Result)
+	copy(data[i:], m.Result)
+	i = encodeVarintQuerier(data, i, uint64(len(m.Result)))
+	i--
+	data[i] = 0x12
+	i -= len(m.ResultType)
+	copy(data[i:], m.ResultType)
+	i = encodeVarintQuerier(data, i, uint64(len(m.ResultType)))
+	i--
+	data[i] = 0xa
+	return len(data) - i, nil
+}
+
+func (m *QueryResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	l := len(m.ResultType)
+	n += 1 + l + sovQuerier(uint64(l))
+	l = len(m.Result)
+	n += 1 + l + sovQuerier(uint64(l))
+	return n
+}
+
+func (m *QueryResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		fieldNum, wireType, n, err := readQuerierTag(data, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = n
+		switch fieldNum {
+		case 1:
+			s, n, err := readQuerierString(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.ResultType = s
+			iNdEx = n
+		case 2:
+			b, n, err := readQuerierBytes(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			m.Result = append(m.Result[:0], b...)
+			iNdEx = n
+		default:
+			n, err := skipQuerier(data, iNdEx, wireType)
+			if err != nil {
+				return err
+			}
+			iNdEx = n
+		}
+	}
+	return nil
+}
+
+// --- minimal wire-format helpers shared by the messages above ---
+
+func encodeVarintQuerier(data []byte, offset int, v uint64) int {
+	offset -= sovQuerier(v)
+	base := offset
+	for v >= 1<<7 {
+		data[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	data[offset] = uint8(v)
+	return base
+}
+
+func sovQuerier(v uint64) (n int) {
+	for {
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	return n
+}
+
+func readQuerierTag(data []byte, index int) (fieldNum int, wireType int, next int, err error) {
+	v, next, err := readQuerierVarintRaw(data, index)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return int(v >> 3), int(v & 0x7), next, nil
+}
+
+func readQuerierVarintRaw(data []byte, index int) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for {
+		if index >= len(data) {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		b := data[index]
+		index++
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, index, nil
+		}
+		shift += 7
+	}
+}
+
+func readQuerierVarint(data []byte, index int, wireType int) (uint64, int, error) {
+	return readQuerierVarintRaw(data, index)
+}
+
+func readQuerierString(data []byte, index int, wireType int) (string, int, error) {
+	b, next, err := readQuerierBytes(data, index, wireType)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b), next, nil
+}
+
+func readQuerierBytes(data []byte, index int, wireType int) ([]byte, int, error) {
+	length, next, err := readQuerierVarintRaw(data, index)
+	if err != nil {
+		return nil, 0, err
+	}
+	end := next + int(length)
+	if end < next || end > len(data) {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	return data[next:end], end, nil
+}
+
+func skipQuerier(data []byte, index int, wireType int) (int, error) {
+	switch wireType {
+	case 0:
+		_, next, err := readQuerierVarintRaw(data, index)
+		return next, err
+	case 2:
+		_, next, err := readQuerierBytes(data, index, wireType)
+		return next, err
+	default:
+		return 0, fmt.Errorf("proto: unsupported wire type %d", wireType)
+	}
+}
+
+// --- gRPC service definition ---
+
+// QuerierServiceClient is the client API for QuerierService.
+type QuerierServiceClient interface {
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	QueryRange(ctx context.Context, in *QueryRangeRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	Labels(ctx context.Context, in *LabelRequest, opts ...grpc.CallOption) (*LabelResponse, error)
+	Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (QuerierService_TailClient, error)
+}
+
+type querierServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+// NewQuerierServiceClient creates a client stub for the QuerierService.
+func NewQuerierServiceClient(cc *grpc.ClientConn) QuerierServiceClient {
+	return &querierServiceClient{cc}
+}
+
+func (c *querierServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, "/logproto.QuerierService/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *querierServiceClient) QueryRange(ctx context.Context, in *QueryRangeRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, "/logproto.QuerierService/QueryRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *querierServiceClient) Labels(ctx context.Context, in *LabelRequest, opts ...grpc.CallOption) (*LabelResponse, error) {
+	out := new(LabelResponse)
+	err := c.cc.Invoke(ctx, "/logproto.QuerierService/Labels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *querierServiceClient) Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (QuerierService_TailClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_QuerierService_serviceDesc.Streams[0], "/logproto.QuerierService/Tail", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &querierServiceTailClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// QuerierService_TailClient is the client-side stream handle for the Tail RPC.
+type QuerierService_TailClient interface {
+	Recv() (*TailResponse, error)
+	grpc.ClientStream
+}
+
+type querierServiceTailClient struct {
+	grpc.ClientStream
+}
+
+func (x *querierServiceTailClient) Recv() (*TailResponse, error) {
+	m := new(TailResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QuerierServiceServer is the server API for QuerierService.
+type QuerierServiceServer interface {
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	QueryRange(context.Context, *QueryRangeRequest) (*QueryResponse, error)
+	Labels(context.Context, *LabelRequest) (*LabelResponse, error)
+	Tail(*TailRequest, QuerierService_TailServer) error
+}
+
+// QuerierService_TailServer is the server-side stream handle for the Tail RPC.
+type QuerierService_TailServer interface {
+	Send(*TailResponse) error
+	grpc.ServerStream
+}
+
+type querierServiceTailServer struct {
+	grpc.ServerStream
+}
+
+func (x *querierServiceTailServer) Send(m *TailResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RegisterQuerierServiceServer registers srv as the implementation of the
+// QuerierService gRPC service on s.
+func RegisterQuerierServiceServer(s *grpc.Server, srv QuerierServiceServer) {
+	s.RegisterService(&_QuerierService_serviceDesc, srv)
+}
+
+func _QuerierService_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuerierServiceServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/logproto.QuerierService/Query"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuerierServiceServer).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuerierService_QueryRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuerierServiceServer).QueryRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/logproto.QuerierService/QueryRange"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuerierServiceServer).QueryRange(ctx, req.(*QueryRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuerierService_Labels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuerierServiceServer).Labels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/logproto.QuerierService/Labels"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuerierServiceServer).Labels(ctx, req.(*LabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuerierService_Tail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuerierServiceServer).Tail(m, &querierServiceTailServer{stream})
+}
+
+var _QuerierService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "logproto.QuerierService",
+	HandlerType: (*QuerierServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Query", Handler: _QuerierService_Query_Handler},
+		{MethodName: "QueryRange", Handler: _QuerierService_QueryRange_Handler},
+		{MethodName: "Labels", Handler: _QuerierService_Labels_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tail",
+			Handler:       _QuerierService_Tail_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/logproto/querier.proto",
+}
+
+// silence "imported and not used" for codes/status, kept for parity with the
+// rest of the generated files in this package which use them for richer
+// error reporting in hand-written RPC implementations.
+var (
+	_ = codes.OK
+	_ = status.New
+)
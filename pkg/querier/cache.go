@@ -0,0 +1,65 @@
+package querier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/weaveworks/common/user"
+)
+
+// QueryCache caches marshaled query responses keyed by a hash of the
+// normalized request parameters plus tenant ID, so that repeated polls for
+// an identical, already-sealed time window don't re-execute the underlying
+// query. Implementations must be safe for concurrent use.
+type QueryCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// lruQueryCache is an in-memory QueryCache backed by a bounded LRU. It's the
+// default QueryCache; a Memcached- or Redis-backed implementation can be
+// plugged in instead via the same interface.
+type lruQueryCache struct {
+	cache *lru.Cache
+}
+
+// NewLRUQueryCache creates a QueryCache holding up to size marshaled
+// responses, evicting the least recently used entry once full.
+func NewLRUQueryCache(size int) (QueryCache, error) {
+	c, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &lruQueryCache{cache: c}, nil
+}
+
+func (c *lruQueryCache) Get(key string) ([]byte, bool) {
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return v.([]byte), true
+}
+
+func (c *lruQueryCache) Set(key string, value []byte) {
+	c.cache.Add(key, value)
+}
+
+// rangeQueryCacheKey hashes the normalized range query parameters together
+// with the requesting tenant's ID, so the same query string from two
+// different tenants never collides in the cache.
+func rangeQueryCacheKey(ctx context.Context, request *rangeQueryRequest) (string, error) {
+	tenantID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%d\x00%d\x00%s\x00%d\x00%s",
+		request.query, request.start.UnixNano(), request.end.UnixNano(),
+		request.step, request.direction, request.limit, tenantID)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,102 @@
+package querier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func mustRangeQueryRequest() *rangeQueryRequest {
+	return &rangeQueryRequest{
+		query:     `{job="foo"}`,
+		start:     time.Unix(0, 0),
+		end:       time.Unix(3600, 0),
+		step:      30 * time.Second,
+		limit:     100,
+		direction: logproto.BACKWARD,
+	}
+}
+
+func TestRangeQueryCacheKey_Deterministic(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "tenant-a")
+	request := mustRangeQueryRequest()
+
+	key1, err := rangeQueryCacheKey(ctx, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key2, err := rangeQueryCacheKey(ctx, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key1 != key2 {
+		t.Fatalf("expected the same request to hash to the same key, got %q and %q", key1, key2)
+	}
+}
+
+func TestRangeQueryCacheKey_TenantIsolation(t *testing.T) {
+	request := mustRangeQueryRequest()
+
+	keyA, err := rangeQueryCacheKey(user.InjectOrgID(context.Background(), "tenant-a"), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keyB, err := rangeQueryCacheKey(user.InjectOrgID(context.Background(), "tenant-b"), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if keyA == keyB {
+		t.Fatalf("expected different tenants to get different cache keys, both got %q", keyA)
+	}
+}
+
+func TestRangeQueryCacheKey_DiffersOnQueryParams(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "tenant-a")
+	base := mustRangeQueryRequest()
+	base64, err := rangeQueryCacheKey(ctx, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changed := mustRangeQueryRequest()
+	changed.limit = base.limit + 1
+	changedKey, err := rangeQueryCacheKey(ctx, changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if base64 == changedKey {
+		t.Fatalf("expected changing limit to change the cache key")
+	}
+}
+
+func TestRangeQueryCacheKey_RequiresTenant(t *testing.T) {
+	if _, err := rangeQueryCacheKey(context.Background(), mustRangeQueryRequest()); err == nil {
+		t.Fatalf("expected an error when no tenant ID is present on the context")
+	}
+}
+
+func TestLRUQueryCache_GetSet(t *testing.T) {
+	cache, err := NewLRUQueryCache(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Fatalf("expected a miss for a key that was never set")
+	}
+
+	cache.Set("a", []byte("value-a"))
+	got, ok := cache.Get("a")
+	if !ok {
+		t.Fatalf("expected a hit for a key that was just set")
+	}
+	if string(got) != "value-a" {
+		t.Fatalf("got %q, want %q", got, "value-a")
+	}
+}
@@ -0,0 +1,107 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/go-kit/kit/log/level"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/weaveworks/common/httpgrpc"
+	"google.golang.org/grpc"
+)
+
+// RegisterQuerierServiceServer registers the QuerierService gRPC service on
+// grpcServer. It exposes the same query and tail functionality as the HTTP
+// handlers, but over HTTP/2 with native gRPC backpressure and flow control,
+// directly using logproto types instead of going through JSON marshaling or
+// a websocket upgrade. Promtail, Grafana and internal services can use it in
+// place of TailHandler.
+func RegisterQuerierServiceServer(grpcServer *grpc.Server, q *Querier) {
+	logproto.RegisterQuerierServiceServer(grpcServer, &querierServiceServer{Querier: q})
+}
+
+// querierServiceServer adapts Querier to the logproto.QuerierServiceServer
+// interface.
+type querierServiceServer struct {
+	*Querier
+}
+
+// queryResponseFromValue marshals a promql.Value with encoding/json, so
+// logproto doesn't have to model PromQL matrices/vectors itself. This is the
+// value's own JSON encoding, not the response body that the HTTP handlers
+// write through marshal.WriteQueryResponseJSON.
+func queryResponseFromValue(v promql.Value) (*logproto.QueryResponse, error) {
+	result, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &logproto.QueryResponse{
+		ResultType: string(v.Type()),
+		Result:     result,
+	}, nil
+}
+
+// Query implements the instant query RPC, the gRPC analog of InstantQueryHandler.
+func (s *querierServiceServer) Query(ctx context.Context, req *logproto.QueryRequest) (*logproto.QueryResponse, error) {
+	query := s.engine.NewInstantQuery(s.Querier, req.Query, req.Ts, req.Direction, req.Limit)
+	result, err := query.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryResponseFromValue(result)
+}
+
+// QueryRange implements the range query RPC, the gRPC analog of RangeQueryHandler.
+func (s *querierServiceServer) QueryRange(ctx context.Context, req *logproto.QueryRangeRequest) (*logproto.QueryResponse, error) {
+	step := time.Duration(req.StepMs) * time.Millisecond
+	query := s.engine.NewRangeQuery(s.Querier, req.Query, req.Start, req.End, step, req.Direction, req.Limit)
+	result, err := query.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryResponseFromValue(result)
+}
+
+// Labels implements the label query RPC, the gRPC analog of LabelHandler.
+func (s *querierServiceServer) Labels(ctx context.Context, req *logproto.LabelRequest) (*logproto.LabelResponse, error) {
+	return s.Querier.Label(ctx, req)
+}
+
+// Tail implements the server-streaming tail RPC, the gRPC analog of
+// TailHandler: no websocket upgrade, no JSON marshaling, just logproto
+// messages pushed to the client as they arrive.
+func (s *querierServiceServer) Tail(req *logproto.TailRequest, stream logproto.QuerierService_TailServer) error {
+	if req.DelayFor > maxDelayForInTailing {
+		return httpgrpc.Errorf(400, "delay_for can't be greater than %d", maxDelayForInTailing)
+	}
+
+	tailer, err := s.Querier.Tail(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tailer.close(); err != nil {
+			level.Error(util.Logger).Log("Error closing Tailer", fmt.Sprintf("%v", err))
+		}
+	}()
+
+	responseChan := tailer.getResponseChan()
+	closeErrChan := tailer.getCloseErrorChan()
+
+	for {
+		select {
+		case response := <-responseChan:
+			if err := stream.Send(toLogprotoTailResponse(response)); err != nil {
+				return err
+			}
+		case err := <-closeErrChan:
+			return err
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package querier
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/url"
@@ -32,6 +36,11 @@ const (
 	defaultSince         = 1 * time.Hour
 	wsPingPeriod         = 1 * time.Second
 	maxDelayForInTailing = 5
+
+	// statusClientClosedRequest is a non-standard HTTP status, following nginx's
+	// convention for code 499, used to signal that the client closed the
+	// connection before the server could finish processing the request.
+	statusClientClosedRequest = 499
 )
 
 // nolint
@@ -223,6 +232,78 @@ type instantQueryRequest struct {
 	direction logproto.Direction
 }
 
+// writeQueryError translates a query execution error into an HTTP response. A
+// context cancellation caused by the client disconnecting is reported as
+// statusClientClosedRequest at debug level instead of the usual 400, so it
+// doesn't show up as noise in error-rate alerts.
+func writeQueryError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+		level.Debug(util.Logger).Log("msg", "query canceled by client", "err", err)
+		http.Error(w, err.Error(), statusClientClosedRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// streamingRequested reports whether the caller opted into the NDJSON
+// streaming response format, either via the `stream` query parameter or the
+// `Accept: application/x-ndjson` header.
+func streamingRequested(r *http.Request) bool {
+	if r.URL.Query().Get("stream") == "true" {
+		return true
+	}
+	return r.Header.Get("Accept") == "application/x-ndjson"
+}
+
+// writeQueryResponseNDJSON writes an already-executed result as newline-delimited
+// JSON, one object per stream or sample, flushing the response after each line.
+// v is still the fully materialized result returned by query.Exec: this does
+// not reduce the engine's peak memory usage, since the engine has no
+// partial-result/iterator path to plumb into yet. What it does avoid is
+// building one single marshaled JSON body before writing anything, so the
+// client can start consuming the response, and freeing marshaled chunks,
+// before the rest has been written. This applies to log-selector results
+// (logql.Streams, returned by LogQueryHandler and a plain log RangeQueryHandler
+// query) as well as the promql.Matrix/Vector results of a metric query.
+func writeQueryResponseNDJSON(v promql.Value, w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+
+	enc := json.NewEncoder(w)
+	switch result := v.(type) {
+	case promql.Matrix:
+		for _, stream := range result {
+			if err := enc.Encode(stream); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	case promql.Vector:
+		for _, sample := range result {
+			if err := enc.Encode(sample); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	case logql.Streams:
+		for _, stream := range result {
+			if err := enc.Encode(stream); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	default:
+		return enc.Encode(v)
+	}
+	return nil
+}
+
 // RangeQueryHandler is a http.HandlerFunc for range queries.
 func (q *Querier) RangeQueryHandler(w http.ResponseWriter, r *http.Request) {
 	// Enforce the query timeout while querying backends
@@ -234,17 +315,66 @@ func (q *Querier) RangeQueryHandler(w http.ResponseWriter, r *http.Request) {
 		server.WriteError(w, err)
 		return
 	}
+
+	// A cache hit serves the marshaled JSON response directly, and a cached
+	// key that matches If-None-Match short-circuits to a 304 without
+	// re-running the query at all. Caching only applies to the `application/json`
+	// response (the NDJSON streaming path below is never served from, or written
+	// to, the cache), and only once the whole requested window can no longer
+	// change, i.e. its end is far enough in the past to be outside the
+	// ingesters' mutable head. A window with a rolling end time (end close to
+	// now, as with a live dashboard) is never cached as a whole; splitting such
+	// a request into a cacheable sealed prefix and a fresh suffix is not
+	// implemented here.
+	var cacheKey, etag string
+	sealed := request.end.Before(time.Now().Add(-q.cfg.MaxCacheFreshness))
+	if q.cache != nil && sealed && !streamingRequested(r) {
+		if cacheKey, err = rangeQueryCacheKey(ctx, request); err == nil {
+			etag = `"` + cacheKey + `"`
+			if r.Header.Get("If-None-Match") == etag {
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			if cached, ok := q.cache.Get(cacheKey); ok {
+				w.Header().Set("ETag", etag)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(cached)
+				return
+			}
+		}
+	}
+
 	query := q.engine.NewRangeQuery(q, request.query, request.start, request.end, request.step, request.direction, request.limit)
 	result, err := query.Exec(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeQueryError(w, r, err)
 		return
 	}
 
-	if err := marshal.WriteQueryResponseJSON(result, w); err != nil {
+	if streamingRequested(r) {
+		if err := writeQueryResponseNDJSON(result, w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if cacheKey == "" {
+		if err := marshal.WriteQueryResponseJSON(result, w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := marshal.WriteQueryResponseJSON(result, &buf); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	q.cache.Set(cacheKey, buf.Bytes())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf.Bytes())
 }
 
 // InstantQueryHandler is a http.HandlerFunc for instant queries.
@@ -261,7 +391,14 @@ func (q *Querier) InstantQueryHandler(w http.ResponseWriter, r *http.Request) {
 	query := q.engine.NewInstantQuery(q, request.query, request.ts, request.direction, request.limit)
 	result, err := query.Exec(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeQueryError(w, r, err)
+		return
+	}
+
+	if streamingRequested(r) {
+		if err := writeQueryResponseNDJSON(result, w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -291,7 +428,14 @@ func (q *Querier) LogQueryHandler(w http.ResponseWriter, r *http.Request) {
 	query := q.engine.NewRangeQuery(q, request.query, request.start, request.end, request.step, request.direction, request.limit)
 	result, err := query.Exec(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeQueryError(w, r, err)
+		return
+	}
+
+	if streamingRequested(r) {
+		if err := writeQueryResponseNDJSON(result, w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -437,3 +581,104 @@ func (q *Querier) TailHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// TailHandlerSSE is a http.HandlerFunc for handling tail queries over Server-Sent
+// Events. It exists alongside TailHandler because some HTTP proxies and load
+// balancers don't properly forward the websocket Upgrade handshake, so clients
+// behind them can fall back to this endpoint instead.
+func (q *Querier) TailHandlerSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	tailRequestPtr, err := httpRequestToTailRequest(r)
+	if err != nil {
+		server.WriteError(w, err)
+		return
+	}
+
+	tailRequestPtr.Query, err = parseRegexQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if tailRequestPtr.DelayFor > maxDelayForInTailing {
+		server.WriteError(w, fmt.Errorf("delay_for can't be greater than %d", maxDelayForInTailing))
+		return
+	}
+
+	// response from httpRequestToQueryRequest is a ptr, if we keep passing pointer down the call then it would stay on
+	// heap until connection to the stream stays open
+	tailRequest := *tailRequestPtr
+
+	tailer, err := q.Tail(r.Context(), &tailRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		if err := tailer.close(); err != nil {
+			level.Error(util.Logger).Log("Error closing Tailer", fmt.Sprintf("%v", err))
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	var response *loghttp_legacy.TailResponse
+	responseChan := tailer.getResponseChan()
+	closeErrChan := tailer.getCloseErrorChan()
+
+	for {
+		select {
+		case response = <-responseChan:
+			if _, err := io.WriteString(w, "event: entry\ndata: "); err != nil {
+				level.Error(util.Logger).Log("Error writing to SSE stream", fmt.Sprintf("%v", err))
+				return
+			}
+
+			if loghttp.GetVersion(r.RequestURI) == loghttp.VersionV1 {
+				err = marshal.WriteTailResponseJSON(*response, w)
+			} else {
+				err = marshal_legacy.WriteTailResponseJSON(*response, w)
+			}
+			if err != nil {
+				level.Error(util.Logger).Log("Error writing to SSE stream", fmt.Sprintf("%v", err))
+				return
+			}
+
+			if _, err := io.WriteString(w, "\n\n"); err != nil {
+				level.Error(util.Logger).Log("Error writing to SSE stream", fmt.Sprintf("%v", err))
+				return
+			}
+			flusher.Flush()
+
+		case err := <-closeErrChan:
+			level.Error(util.Logger).Log("Error from iterator", fmt.Sprintf("%v", err))
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+			flusher.Flush()
+			return
+
+		case <-ticker.C:
+			// Comment lines are ignored by SSE clients but keep intermediaries from
+			// timing out the connection when there are no entries to send.
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				level.Error(util.Logger).Log("Error writing ping to SSE stream", fmt.Sprintf("%v", err))
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/pkg/logql"
+)
+
+func decodeNDJSONLines(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if line == "" {
+			continue
+		}
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", line, err)
+		}
+		lines = append(lines, v)
+	}
+	return lines
+}
+
+func TestWriteQueryResponseNDJSON_Matrix(t *testing.T) {
+	matrix := promql.Matrix{
+		{Metric: labels.FromStrings("job", "a")},
+		{Metric: labels.FromStrings("job", "b")},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := writeQueryResponseNDJSON(matrix, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := decodeNDJSONLines(t, rec.Body.String())
+	if len(lines) != len(matrix) {
+		t.Fatalf("expected %d NDJSON lines, one per series, got %d", len(matrix), len(lines))
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("got Content-Type %q, want application/x-ndjson", ct)
+	}
+}
+
+func TestWriteQueryResponseNDJSON_Vector(t *testing.T) {
+	vector := promql.Vector{
+		{Metric: labels.FromStrings("job", "a")},
+		{Metric: labels.FromStrings("job", "b")},
+		{Metric: labels.FromStrings("job", "c")},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := writeQueryResponseNDJSON(vector, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := decodeNDJSONLines(t, rec.Body.String())
+	if len(lines) != len(vector) {
+		t.Fatalf("expected %d NDJSON lines, one per sample, got %d", len(vector), len(lines))
+	}
+}
+
+func TestWriteQueryResponseNDJSON_Streams(t *testing.T) {
+	streams := logql.Streams{
+		{Labels: `{job="a"}`},
+		{Labels: `{job="b"}`},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := writeQueryResponseNDJSON(streams, rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := decodeNDJSONLines(t, rec.Body.String())
+	if len(lines) != len(streams) {
+		t.Fatalf("expected %d NDJSON lines, one per stream, got %d", len(streams), len(lines))
+	}
+}
+
+func TestWriteQueryError(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		err        error
+		cancel     bool
+		wantStatus int
+	}{
+		{
+			name:       "client cancellation maps to statusClientClosedRequest",
+			err:        context.Canceled,
+			cancel:     true,
+			wantStatus: statusClientClosedRequest,
+		},
+		{
+			name:       "context.Canceled without a done request context is a normal 400",
+			err:        context.Canceled,
+			cancel:     false,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "other errors stay 400",
+			err:        errors.New("boom"),
+			cancel:     false,
+			wantStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancel {
+				cancel()
+			} else {
+				defer cancel()
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			writeQueryError(rec, r, tc.err)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d", rec.Code, tc.wantStatus)
+			}
+		})
+	}
+}
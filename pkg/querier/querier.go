@@ -0,0 +1,76 @@
+package querier
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
+)
+
+// Config holds the configuration for a Querier.
+type Config struct {
+	QueryTimeout    time.Duration `yaml:"query_timeout"`
+	TailMaxDuration time.Duration `yaml:"tail_max_duration"`
+
+	// MaxCacheFreshness bounds how close to "now" the end of a range query
+	// may be and still be considered safe to cache: a window ending within
+	// this duration of the current time can still gain new log lines, so
+	// Querier never reads from or writes to the QueryCache for it.
+	MaxCacheFreshness time.Duration `yaml:"max_cache_freshness"`
+}
+
+// RegisterFlags registers flags for Config.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.QueryTimeout, "querier.query-timeout", 1*time.Minute, "Timeout when querying backends (ingesters or storage) during the execution of a query request.")
+	f.DurationVar(&cfg.TailMaxDuration, "querier.tail-max-duration", 1*time.Hour, "Limit the duration for which a tail request is served.")
+	f.DurationVar(&cfg.MaxCacheFreshness, "querier.max-cache-freshness", 1*time.Minute, "Most recent allowed cacheable result, to avoid caching a window that can still change.")
+}
+
+// Store is the subset of the backing log store that Querier needs in order
+// to answer label and tail requests; query execution itself goes through
+// engine instead. A concrete implementation fans requests out to the
+// ingesters and/or long-term storage.
+type Store interface {
+	Label(ctx context.Context, req *logproto.LabelRequest) (*logproto.LabelResponse, error)
+	Tail(ctx context.Context, req *logproto.TailRequest) (TailClient, error)
+}
+
+// Querier handles read path requests for logs, metrics and labels, wiring
+// the query engine and the backing Store to the HTTP and gRPC handlers in
+// this package.
+type Querier struct {
+	cfg    Config
+	engine *logql.Engine
+	store  Store
+	cache  QueryCache
+}
+
+// New creates a new Querier. cache may be nil, in which case range query
+// responses are never read from or written to a QueryCache.
+func New(cfg Config, engine *logql.Engine, store Store, cache QueryCache) *Querier {
+	return &Querier{
+		cfg:    cfg,
+		engine: engine,
+		store:  store,
+		cache:  cache,
+	}
+}
+
+// Label handles label name/value requests by delegating to the backing Store.
+func (q *Querier) Label(ctx context.Context, req *logproto.LabelRequest) (*logproto.LabelResponse, error) {
+	return q.store.Label(ctx, req)
+}
+
+// Tail opens a live tail of entries matching req against the backing Store
+// and wraps it in a Tailer, which TailHandler, TailHandlerSSE and the gRPC
+// Tail RPC all drain until the client disconnects or the Store closes the
+// stream.
+func (q *Querier) Tail(ctx context.Context, req *logproto.TailRequest) (*Tailer, error) {
+	client, err := q.store.Tail(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return newTailer(client), nil
+}
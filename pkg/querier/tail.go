@@ -0,0 +1,86 @@
+package querier
+
+import (
+	loghttp_legacy "github.com/grafana/loki/pkg/loghttp/legacy"
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// TailClient is a single backing source of tailed entries, e.g. a stream
+// opened against one ingester. Each Tailer reads from exactly one TailClient.
+type TailClient interface {
+	Recv() (*logproto.TailResponse, error)
+	Close() error
+}
+
+// Tailer fans a TailClient's raw logproto.TailResponse messages into the
+// loghttp_legacy representation that TailHandler and TailHandlerSSE write
+// out, and that querierServiceServer.Tail converts back to logproto for the
+// gRPC path.
+type Tailer struct {
+	client TailClient
+
+	responseChan chan *loghttp_legacy.TailResponse
+	closeErrChan chan error
+	quit         chan struct{}
+}
+
+func newTailer(client TailClient) *Tailer {
+	t := &Tailer{
+		client:       client,
+		responseChan: make(chan *loghttp_legacy.TailResponse),
+		closeErrChan: make(chan error, 1),
+		quit:         make(chan struct{}),
+	}
+	go t.loop()
+	return t
+}
+
+func (t *Tailer) loop() {
+	for {
+		response, err := t.client.Recv()
+		if err != nil {
+			t.closeErrChan <- err
+			return
+		}
+
+		legacyResponse := loghttp_legacy.NewTailResponse(*response)
+		select {
+		case t.responseChan <- &legacyResponse:
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+func (t *Tailer) getResponseChan() <-chan *loghttp_legacy.TailResponse {
+	return t.responseChan
+}
+
+func (t *Tailer) getCloseErrorChan() <-chan error {
+	return t.closeErrChan
+}
+
+func (t *Tailer) close() error {
+	close(t.quit)
+	return t.client.Close()
+}
+
+// toLogprotoTailResponse converts a loghttp_legacy.TailResponse back into
+// a logproto.TailResponse, so the gRPC Tail RPC can send the wire type
+// directly instead of the HTTP-oriented legacy one. A legacy dropped entry
+// keeps only one timestamp, so each rebuilt DroppedStream has From and To
+// both set to it.
+func toLogprotoTailResponse(r *loghttp_legacy.TailResponse) *logproto.TailResponse {
+	dropped := make([]*logproto.DroppedStream, 0, len(r.DroppedEntries))
+	for _, d := range r.DroppedEntries {
+		dropped = append(dropped, &logproto.DroppedStream{
+			From:   d.Timestamp,
+			To:     d.Timestamp,
+			Labels: d.Labels,
+		})
+	}
+	return &logproto.TailResponse{
+		Streams:        r.Streams,
+		DroppedStreams: dropped,
+	}
+}